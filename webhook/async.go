@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/sunmyinf/go-workplace/decode"
+)
+
+// defaultMaxAttempts is used when a RetryPolicy is the zero value: a
+// handler registered without HandleObjectFuncWithRetry still runs once.
+const defaultMaxAttempts = 1
+
+// RetryPolicy controls how many times, and how long to wait between,
+// retries of an object handler run through the async dispatcher.
+// Backoff doubles on each attempt and is jittered by up to half its
+// value.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return defaultMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.Backoff <= 0 {
+		return 0
+	}
+	delay := p.Backoff << uint(attempt-1)
+	return delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// DeadLetterFunc is called when an object handler exhausts its
+// RetryPolicy's attempts while running under async dispatch.
+type DeadLetterFunc func(pattern string, object decode.Object, reqBody decode.RequestBody, err error)
+
+type asyncJob struct {
+	pattern    string
+	reqBody    decode.RequestBody
+	deliveryID string
+}
+
+// EnableAsyncDispatch switches Server to async mode: getObjectHandlerFunc
+// responds 200 OK as soon as the signature verifies and enqueues the
+// decoded event, instead of blocking the Workplace request on the object
+// handler. workers goroutines drain the queueSize-buffered queue and run
+// handlers with their registered RetryPolicy. If the queue is full, the
+// handler responds 503 so Workplace retries the delivery.
+func (ws *Server) EnableAsyncDispatch(workers, queueSize int) {
+	ws.asyncQueue = make(chan asyncJob, queueSize)
+	for i := 0; i < workers; i++ {
+		go ws.asyncWorker()
+	}
+}
+
+func (ws *Server) asyncWorker() {
+	for job := range ws.asyncQueue {
+		ws.runWithRetry(job)
+	}
+}
+
+func (ws *Server) runWithRetry(job asyncJob) {
+	ctx := withDeliveryID(context.Background(), job.deliveryID)
+
+	handler, exist := ws.objectHandlers[job.pattern][job.reqBody.Object]
+	if !exist {
+		return
+	}
+	policy := ws.retryPolicies[job.pattern][job.reqBody.Object]
+
+	var err error
+	start := time.Now()
+	for attempt := 1; attempt <= policy.maxAttempts(); attempt++ {
+		if err = handler(job.reqBody); err == nil {
+			break
+		}
+		if attempt < policy.maxAttempts() {
+			time.Sleep(policy.backoff(attempt))
+		}
+	}
+	ws.observer().OnHandlerResult(ctx, job.pattern, job.reqBody.Object, time.Since(start), err)
+
+	if err != nil {
+		// Release the claim so a genuine redelivery from Workplace can
+		// still be processed instead of being silently swallowed.
+		ws.deliveryStore().Release(job.deliveryID)
+		if ws.DeadLetter != nil {
+			ws.DeadLetter(job.pattern, job.reqBody.Object, job.reqBody, err)
+		}
+	}
+}
+
+// HandleObjectFuncWithRetry registers objectHandler like HandleObjectFunc
+// does, additionally recording policy so async dispatch retries it on
+// failure before giving up and calling Server.DeadLetter.
+func (ws *Server) HandleObjectFuncWithRetry(pattern string, object decode.Object, objectHandler func(decode.RequestBody) error, policy RetryPolicy) {
+	ws.HandleObjectFunc(pattern, object, objectHandler)
+
+	if ws.retryPolicies == nil {
+		ws.retryPolicies = make(map[string]map[decode.Object]RetryPolicy)
+	}
+	if ws.retryPolicies[pattern] == nil {
+		ws.retryPolicies[pattern] = make(map[decode.Object]RetryPolicy)
+	}
+	ws.retryPolicies[pattern][object] = policy
+}
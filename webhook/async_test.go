@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sunmyinf/go-workplace/decode"
+)
+
+func TestRetryPolicyMaxAttemptsDefaultsToOne(t *testing.T) {
+	var p RetryPolicy
+	if got := p.maxAttempts(); got != 1 {
+		t.Fatalf("expected default max attempts of 1, got %d", got)
+	}
+}
+
+func TestRetryPolicyBackoffIsZeroWithoutConfiguredBackoff(t *testing.T) {
+	var p RetryPolicy
+	if got := p.backoff(1); got != 0 {
+		t.Fatalf("expected zero backoff when unconfigured, got %s", got)
+	}
+}
+
+func TestRetryPolicyBackoffGrowsAndStaysJittered(t *testing.T) {
+	p := RetryPolicy{Backoff: 10 * time.Millisecond}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		base := p.Backoff << uint(attempt-1)
+		delay := p.backoff(attempt)
+		if delay < base || delay > base+base/2 {
+			t.Fatalf("attempt %d: expected delay in [%s, %s], got %s", attempt, base, base+base/2, delay)
+		}
+	}
+}
+
+func TestHandleObjectFuncWithRetryRegistersHandlerAndPolicy(t *testing.T) {
+	ws := NewServer("secret", "token", "verify")
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}
+
+	ws.HandleObjectFuncWithRetry("/webhook", "page", func(decode.RequestBody) error { return nil }, policy)
+
+	if _, exist := ws.objectHandlers["/webhook"]["page"]; !exist {
+		t.Fatal("expected HandleObjectFuncWithRetry to register the object handler")
+	}
+	if got := ws.retryPolicies["/webhook"]["page"]; got != policy {
+		t.Fatalf("expected stored retry policy %+v, got %+v", policy, got)
+	}
+}
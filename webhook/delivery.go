@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// defaultDeliveryStoreCapacity is used by NewMemoryDeliveryStore when
+// capacity is <= 0.
+const defaultDeliveryStoreCapacity = 10000
+
+// DeliveryStore lets Server de-duplicate webhook deliveries. Workplace
+// may redeliver the same payload, e.g. after a 5xx response or a manual
+// replay; Claim and Release together track which deliveries have been
+// taken for processing.
+type DeliveryStore interface {
+	// Claim atomically marks id as being processed and reports whether
+	// this call claimed it: false means id was already claimed.
+	Claim(id string, at time.Time) (claimed bool, err error)
+	// Release undoes a Claim, e.g. because the delivery failed to
+	// process and id should remain eligible for redelivery.
+	Release(id string) error
+}
+
+// noopDeliveryStore is the default DeliveryStore when Server isn't given
+// one: every Claim succeeds and Release is a no-op.
+type noopDeliveryStore struct{}
+
+func (noopDeliveryStore) Claim(id string, at time.Time) (bool, error) { return true, nil }
+func (noopDeliveryStore) Release(id string) error                     { return nil }
+
+type deliveryEntry struct {
+	id string
+	at time.Time
+}
+
+// memoryDeliveryStore is a fixed-capacity, in-memory LRU DeliveryStore.
+// It is safe for concurrent use.
+type memoryDeliveryStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	index    map[string]*list.Element
+}
+
+// NewMemoryDeliveryStore returns a DeliveryStore backed by an in-memory
+// LRU of at most capacity deliveries. A capacity <= 0 falls back to
+// defaultDeliveryStoreCapacity.
+func NewMemoryDeliveryStore(capacity int) DeliveryStore {
+	if capacity <= 0 {
+		capacity = defaultDeliveryStoreCapacity
+	}
+	return &memoryDeliveryStore{
+		capacity: capacity,
+		order:    list.New(),
+		index:    make(map[string]*list.Element),
+	}
+}
+
+func (s *memoryDeliveryStore) Claim(id string, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.index[id]; ok {
+		el.Value.(*deliveryEntry).at = at
+		s.order.MoveToFront(el)
+		return false, nil
+	}
+
+	s.index[id] = s.order.PushFront(&deliveryEntry{id: id, at: at})
+	if s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.index, oldest.Value.(*deliveryEntry).id)
+	}
+	return true, nil
+}
+
+func (s *memoryDeliveryStore) Release(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.index[id]; ok {
+		s.order.Remove(el)
+		delete(s.index, id)
+	}
+	return nil
+}
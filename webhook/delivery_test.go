@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNoopDeliveryStoreAlwaysClaims(t *testing.T) {
+	s := noopDeliveryStore{}
+
+	claimed, err := s.Claim("id-1", time.Now())
+	if err != nil || !claimed {
+		t.Fatalf("expected noop store to claim, got claimed=%v err=%v", claimed, err)
+	}
+	claimed, err = s.Claim("id-1", time.Now())
+	if err != nil || !claimed {
+		t.Fatalf("expected noop store to claim again, got claimed=%v err=%v", claimed, err)
+	}
+}
+
+func TestMemoryDeliveryStoreClaimIsOnceOnly(t *testing.T) {
+	s := NewMemoryDeliveryStore(10)
+
+	claimed, err := s.Claim("id-1", time.Now())
+	if err != nil || !claimed {
+		t.Fatalf("first claim should succeed, got claimed=%v err=%v", claimed, err)
+	}
+
+	claimed, err = s.Claim("id-1", time.Now())
+	if err != nil || claimed {
+		t.Fatalf("second claim of the same id should report already claimed, got claimed=%v err=%v", claimed, err)
+	}
+}
+
+func TestMemoryDeliveryStoreReleaseAllowsReclaim(t *testing.T) {
+	s := NewMemoryDeliveryStore(10)
+
+	if _, err := s.Claim("id-1", time.Now()); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	if err := s.Release("id-1"); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+
+	claimed, err := s.Claim("id-1", time.Now())
+	if err != nil || !claimed {
+		t.Fatalf("expected reclaim after release to succeed, got claimed=%v err=%v", claimed, err)
+	}
+}
+
+func TestMemoryDeliveryStoreEvictsOldestBeyondCapacity(t *testing.T) {
+	s := NewMemoryDeliveryStore(2)
+	now := time.Now()
+
+	s.Claim("id-1", now)
+	s.Claim("id-2", now)
+	s.Claim("id-3", now) // should evict id-1
+
+	claimed, _ := s.Claim("id-1", now)
+	if !claimed {
+		t.Fatal("expected id-1 to have been evicted and therefore reclaimable")
+	}
+}
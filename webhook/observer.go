@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/sunmyinf/go-workplace/decode"
+)
+
+// deliveryIDContextKey carries the X-Hub-Delivery (or derived) delivery
+// ID through request and, for async dispatch, job context so Observer
+// implementations can attach it as a log or span attribute.
+const deliveryIDContextKey contextKey = 1
+
+// ContextDeliveryID returns the delivery ID Server attached to ctx, and
+// whether one was present.
+func ContextDeliveryID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(deliveryIDContextKey).(string)
+	return id, ok
+}
+
+func withDeliveryID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, deliveryIDContextKey, id)
+}
+
+// Observer receives lifecycle events from Server's dispatcher, so
+// production deployments can plug in logging, metrics, or tracing
+// instead of the bare status codes Server writes on its own.
+type Observer interface {
+	// OnRequest fires once a delivery has been decoded, matched to an
+	// object handler and claimed as new; duplicates don't trigger it.
+	OnRequest(ctx context.Context, pattern string, object decode.Object)
+	// OnVerifyFail fires when signature verification fails.
+	OnVerifyFail(ctx context.Context, err error)
+	// OnDecodeFail fires when the payload can't be unmarshalled into
+	// decode.RequestBody.
+	OnDecodeFail(ctx context.Context, err error, rawBody []byte)
+	// OnHandlerResult fires after an object handler returns, whether run
+	// synchronously or under async dispatch, where it fires once after the
+	// final attempt (success or retries exhausted).
+	OnHandlerResult(ctx context.Context, pattern string, object decode.Object, dur time.Duration, err error)
+}
+
+// noopObserver is the default Observer: every method is a no-op, leaving
+// dispatch instrumentation opt-in.
+type noopObserver struct{}
+
+func (noopObserver) OnRequest(context.Context, string, decode.Object)                             {}
+func (noopObserver) OnVerifyFail(context.Context, error)                                          {}
+func (noopObserver) OnDecodeFail(context.Context, error, []byte)                                  {}
+func (noopObserver) OnHandlerResult(context.Context, string, decode.Object, time.Duration, error) {}
+
+func (ws *Server) observer() Observer {
+	if ws.Observer == nil {
+		return noopObserver{}
+	}
+	return ws.Observer
+}
+
+// AsyncQueueDepth returns the number of events currently buffered for
+// async dispatch, or 0 if EnableAsyncDispatch hasn't been called. It's
+// meant to be polled by an Observer such as prometheus.Observer.
+func (ws *Server) AsyncQueueDepth() int {
+	if ws.asyncQueue == nil {
+		return 0
+	}
+	return len(ws.asyncQueue)
+}
@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sunmyinf/go-workplace/decode"
+)
+
+// SlogObserver is an Observer backed by log/slog. It attaches the
+// delivery ID from the request context, when present, so individual
+// Meta deliveries can be traced across log lines.
+type SlogObserver struct {
+	Logger *slog.Logger
+}
+
+// NewSlogObserver returns a SlogObserver logging through logger. A nil
+// logger falls back to slog.Default().
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogObserver{Logger: logger}
+}
+
+func (o *SlogObserver) attrs(ctx context.Context, extra ...any) []any {
+	if id, ok := ContextDeliveryID(ctx); ok {
+		extra = append(extra, slog.String("delivery_id", id))
+	}
+	return extra
+}
+
+func (o *SlogObserver) OnRequest(ctx context.Context, pattern string, object decode.Object) {
+	o.Logger.InfoContext(ctx, "workplace: request",
+		o.attrs(ctx, slog.String("pattern", pattern), slog.String("object", string(object)))...)
+}
+
+func (o *SlogObserver) OnVerifyFail(ctx context.Context, err error) {
+	o.Logger.WarnContext(ctx, "workplace: signature verification failed",
+		o.attrs(ctx, slog.Any("error", err))...)
+}
+
+func (o *SlogObserver) OnDecodeFail(ctx context.Context, err error, rawBody []byte) {
+	o.Logger.WarnContext(ctx, "workplace: payload decode failed",
+		o.attrs(ctx, slog.Any("error", err), slog.Int("raw_body_len", len(rawBody)))...)
+}
+
+func (o *SlogObserver) OnHandlerResult(ctx context.Context, pattern string, object decode.Object, dur time.Duration, err error) {
+	attrs := o.attrs(ctx,
+		slog.String("pattern", pattern),
+		slog.String("object", string(object)),
+		slog.Duration("duration", dur),
+	)
+	if err != nil {
+		o.Logger.ErrorContext(ctx, "workplace: handler failed", append(attrs, slog.Any("error", err))...)
+		return
+	}
+	o.Logger.InfoContext(ctx, "workplace: handler succeeded", attrs...)
+}
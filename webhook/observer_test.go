@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextDeliveryIDRoundTrips(t *testing.T) {
+	ctx := withDeliveryID(context.Background(), "delivery-123")
+
+	id, ok := ContextDeliveryID(ctx)
+	if !ok || id != "delivery-123" {
+		t.Fatalf("expected delivery-123, got %q ok=%v", id, ok)
+	}
+}
+
+func TestContextDeliveryIDMissing(t *testing.T) {
+	if _, ok := ContextDeliveryID(context.Background()); ok {
+		t.Fatal("expected no delivery ID on a bare context")
+	}
+}
+
+func TestServerObserverDefaultsToNoop(t *testing.T) {
+	ws := &Server{}
+	if _, ok := ws.observer().(noopObserver); !ok {
+		t.Fatalf("expected noopObserver by default, got %T", ws.observer())
+	}
+}
@@ -0,0 +1,79 @@
+// Package prometheus adapts webhook.Observer to Prometheus metrics. It's a
+// separate package from webhook itself so that importing webhook
+// doesn't pull in github.com/prometheus/client_golang for callers who
+// don't want it.
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/sunmyinf/go-workplace-webhook/webhook"
+	"github.com/sunmyinf/go-workplace/decode"
+)
+
+// Observer is a webhook.Observer that exposes verification failures, a
+// handler latency histogram keyed by pattern and object, and, when
+// queueDepth is set, the async dispatch queue depth.
+type Observer struct {
+	verifyFailures  prom.Counter
+	decodeFailures  prom.Counter
+	handlerDuration *prom.HistogramVec
+	handlerErrors   *prom.CounterVec
+	queueDepth      prom.GaugeFunc
+}
+
+var _ webhook.Observer = (*Observer)(nil)
+
+// NewObserver registers its collectors with registerer and returns an
+// Observer ready to assign to Server.Observer. queueDepth is typically
+// Server.AsyncQueueDepth; pass nil if async dispatch is disabled.
+func NewObserver(registerer prom.Registerer, queueDepth func() int) *Observer {
+	o := &Observer{
+		verifyFailures: prom.NewCounter(prom.CounterOpts{
+			Name: "workplace_webhook_verify_failures_total",
+			Help: "Number of requests that failed signature verification.",
+		}),
+		decodeFailures: prom.NewCounter(prom.CounterOpts{
+			Name: "workplace_webhook_decode_failures_total",
+			Help: "Number of requests whose payload failed to decode.",
+		}),
+		handlerDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Name: "workplace_webhook_handler_duration_seconds",
+			Help: "Object handler latency, by pattern and object.",
+		}, []string{"pattern", "object"}),
+		handlerErrors: prom.NewCounterVec(prom.CounterOpts{
+			Name: "workplace_webhook_handler_errors_total",
+			Help: "Number of object handler runs that returned an error, by pattern and object.",
+		}, []string{"pattern", "object"}),
+	}
+	registerer.MustRegister(o.verifyFailures, o.decodeFailures, o.handlerDuration, o.handlerErrors)
+
+	if queueDepth != nil {
+		o.queueDepth = prom.NewGaugeFunc(prom.GaugeOpts{
+			Name: "workplace_webhook_async_queue_depth",
+			Help: "Number of events currently buffered for async dispatch.",
+		}, func() float64 { return float64(queueDepth()) })
+		registerer.MustRegister(o.queueDepth)
+	}
+
+	return o
+}
+
+func (o *Observer) OnRequest(context.Context, string, decode.Object) {}
+
+func (o *Observer) OnVerifyFail(context.Context, error) {
+	o.verifyFailures.Inc()
+}
+
+func (o *Observer) OnDecodeFail(context.Context, error, []byte) {
+	o.decodeFailures.Inc()
+}
+
+func (o *Observer) OnHandlerResult(_ context.Context, pattern string, object decode.Object, dur time.Duration, err error) {
+	o.handlerDuration.WithLabelValues(pattern, string(object)).Observe(dur.Seconds())
+	if err != nil {
+		o.handlerErrors.WithLabelValues(pattern, string(object)).Inc()
+	}
+}
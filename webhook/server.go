@@ -1,32 +1,49 @@
 package webhook
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
-	"errors"
+	"io"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/sunmyinf/go-workplace/decode"
 )
 
 // Server is to launch server to serve workplace webhook callback request.
 type Server struct {
-	Secret            string
+	Secrets           []string
+	AppID             string
 	AccessToken       string
 	VerificationToken string
-	objectHandlers    map[string]map[decode.Object]func(decode.RequestBody) error
-	mux               *http.ServeMux
+	// DeliveryStore de-duplicates redelivered webhooks. If nil, Server
+	// falls back to a no-op store and processes every delivery.
+	DeliveryStore DeliveryStore
+	// DeadLetter, if set, is called when an async object handler exhausts
+	// its RetryPolicy. Unused unless EnableAsyncDispatch was called.
+	DeadLetter DeadLetterFunc
+	// Observer, if set, receives dispatch lifecycle events for logging,
+	// metrics, or tracing. Defaults to a no-op.
+	Observer       Observer
+	objectHandlers map[string]map[decode.Object]func(decode.RequestBody) error
+	retryPolicies  map[string]map[decode.Object]RetryPolicy
+	asyncQueue     chan asyncJob
+	mux            *http.ServeMux
 }
 
 // NewServer return workplace webhook server instance.
 // Handler has been registered to it as '/webhook' pattern by default.
 func NewServer(secret, accessToken, verificationToken string) *Server {
+	return NewServerWithSecrets(accessToken, verificationToken, secret)
+}
+
+// NewServerWithSecrets returns a workplace webhook server instance that
+// accepts signatures produced by any one of secrets, to support rotating
+// the app secret without downtime.
+// Handler has been registered to it as '/webhook' pattern by default.
+func NewServerWithSecrets(accessToken, verificationToken string, secrets ...string) *Server {
 	ws := &Server{
-		Secret:            secret,
+		Secrets:           secrets,
 		AccessToken:       accessToken,
 		VerificationToken: verificationToken,
 		mux:               http.NewServeMux(),
@@ -83,70 +100,110 @@ func (ws *Server) webhookHandlerFunc(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+// deliveryStore returns ws.DeliveryStore, or a store that claims every
+// delivery as new when Server hasn't been given one to use.
+func (ws *Server) deliveryStore() DeliveryStore {
+	if ws.DeliveryStore == nil {
+		return noopDeliveryStore{}
+	}
+	return ws.DeliveryStore
+}
+
+// deliveryID identifies a single Workplace delivery, preferring
+// the X-Hub-Delivery header Meta sends and falling back to a hash of the
+// raw payload for older deliveries that omit it.
+func deliveryID(r *http.Request, payload []byte) string {
+	if id := r.Header.Get("X-Hub-Delivery"); id != "" {
+		return id
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// getObjectHandlerFunc is a thin consumer of Verifier: it delegates
+// signature verification and body decoding to it, then dispatches on the
+// decoded object.
 func (ws *Server) getObjectHandlerFunc(pattern string) http.HandlerFunc {
+	verifier := NewVerifier(ws.Secrets...)
+	verifier.OnVerifyFail = ws.observer().OnVerifyFail
+	verifier.OnDecodeFail = ws.observer().OnDecodeFail
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			// Validate request payloads
-			bufBody := bytes.Buffer{}
-			if _, err := bufBody.ReadFrom(r.Body); err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-				return
-			}
-			if err := verifySignature(r.Header.Get("X-Hub-Signature"), ws.Secret, bufBody.Bytes()); err != nil {
-				w.WriteHeader(http.StatusForbidden)
-				return
-			}
-
-			// Parse payloads
-			reqBody := decode.RequestBody{}
-			if err := json.Unmarshal(bufBody.Bytes(), &reqBody); err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-				return
-			}
-
-			// Switch handler by pattern and object
-			objectHandlerMap, exist := ws.objectHandlers[pattern]
-			if !exist {
-				// if pattern not registered, return ok status.
-				w.WriteHeader(http.StatusOK)
-				return
-			}
-			handler, exist := objectHandlerMap[reqBody.Object]
-			if !exist {
-				// if object handler not registered, return ok status.
-				w.WriteHeader(http.StatusOK)
-				return
-			}
-			if err := handler(reqBody); err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-			} else {
-				w.WriteHeader(http.StatusOK)
-			}
+			// X-Hub-Delivery doesn't depend on the signature or the
+			// decoded body, so attach it before verification runs and
+			// OnVerifyFail/OnDecodeFail can be traced to the delivery
+			// that triggered them too.
+			r = r.WithContext(withDeliveryID(r.Context(), r.Header.Get("X-Hub-Delivery")))
+
+			verifier.WrapFunc(func(w http.ResponseWriter, r *http.Request) {
+				reqBody, _ := ContextRequestBody(r)
+
+				payload, err := io.ReadAll(r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				id := deliveryID(r, payload)
+				ctx := withDeliveryID(r.Context(), id)
+				r = r.WithContext(ctx)
+
+				// Switch handler by pattern and object
+				objectHandlerMap, exist := ws.objectHandlers[pattern]
+				if !exist {
+					// if pattern not registered, return ok status.
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				handler, exist := objectHandlerMap[reqBody.Object]
+				if !exist {
+					// if object handler not registered, return ok status.
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+
+				// Claim the delivery only once we know it'll actually be
+				// dispatched, and only release it if dispatch doesn't
+				// durably succeed, so a failed or throttled delivery can
+				// still be retried by Workplace instead of being dropped.
+				claimed, err := ws.deliveryStore().Claim(id, time.Now())
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				if !claimed {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+
+				ws.observer().OnRequest(ctx, pattern, reqBody.Object)
+
+				if ws.asyncQueue != nil {
+					select {
+					case ws.asyncQueue <- asyncJob{pattern: pattern, reqBody: reqBody, deliveryID: id}:
+						w.WriteHeader(http.StatusOK)
+					default:
+						// Queue is full; release the claim and ask
+						// Workplace to retry later.
+						ws.deliveryStore().Release(id)
+						w.WriteHeader(http.StatusServiceUnavailable)
+					}
+					return
+				}
+
+				start := time.Now()
+				err = handler(reqBody)
+				ws.observer().OnHandlerResult(ctx, pattern, reqBody.Object, time.Since(start), err)
+				if err != nil {
+					ws.deliveryStore().Release(id)
+					w.WriteHeader(http.StatusBadRequest)
+				} else {
+					w.WriteHeader(http.StatusOK)
+				}
+			})(w, r)
 		default:
 			w.WriteHeader(http.StatusForbidden)
 		}
 		return
 	})
 }
-
-func verifySignature(sig, secret string, payload []byte) error {
-	if sig == "" {
-		return errors.New("error: signature is empty")
-	}
-
-	elements := strings.Split(sig, "=")
-	if len(elements) < 2 {
-		return errors.New("errors: invalid signature")
-	}
-	signatureHash := elements[1]
-
-	mac := hmac.New(sha1.New, []byte(secret))
-	mac.Write(payload)
-	expectedHash := hex.EncodeToString(mac.Sum(nil))
-
-	if signatureHash != expectedHash {
-		return errors.New("error: signature hash do not match expected hash")
-	}
-	return nil
-}
@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sunmyinf/go-workplace/decode"
+)
+
+func postWebhook(t *testing.T, handler http.HandlerFunc, deliveryID string, payload []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	req.Header.Set("X-Hub-Signature-256", sha256Signature("secret", payload))
+	if deliveryID != "" {
+		req.Header.Set("X-Hub-Delivery", deliveryID)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func TestServerDuplicateDeliveryShortCircuits(t *testing.T) {
+	ws := NewServer("secret", "token", "verify")
+	ws.DeliveryStore = NewMemoryDeliveryStore(10)
+
+	var calls int
+	ws.HandleObjectFunc("/webhook", "page", func(decode.RequestBody) error {
+		calls++
+		return nil
+	})
+	handler := ws.getObjectHandlerFunc("/webhook")
+
+	payload := []byte(`{"object":"page"}`)
+	rec1 := postWebhook(t, handler, "delivery-1", payload)
+	rec2 := postWebhook(t, handler, "delivery-1", payload)
+
+	if rec1.Code != http.StatusOK || rec2.Code != http.StatusOK {
+		t.Fatalf("expected both responses to be 200, got %d and %d", rec1.Code, rec2.Code)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the handler to run once for a redelivered event, ran %d times", calls)
+	}
+}
+
+func TestServerAsyncQueueFullReturns503(t *testing.T) {
+	ws := NewServer("secret", "token", "verify")
+	ws.HandleObjectFunc("/webhook", "page", func(decode.RequestBody) error { return nil })
+	ws.EnableAsyncDispatch(0, 1) // no workers draining, so the queue fills up
+	handler := ws.getObjectHandlerFunc("/webhook")
+
+	payload := []byte(`{"object":"page"}`)
+	rec1 := postWebhook(t, handler, "delivery-1", payload)
+	rec2 := postWebhook(t, handler, "delivery-2", payload)
+
+	if rec1.Code != http.StatusOK {
+		t.Fatalf("expected the first delivery to be enqueued with 200, got %d", rec1.Code)
+	}
+	if rec2.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected the second delivery to be rejected with 503 once the queue is full, got %d", rec2.Code)
+	}
+}
+
+type recordingObserver struct {
+	mu            sync.Mutex
+	sawVerifyFail bool
+	verifyFailID  string
+	requestID     string
+	resultID      string
+}
+
+func (o *recordingObserver) OnRequest(ctx context.Context, pattern string, object decode.Object) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.requestID, _ = ContextDeliveryID(ctx)
+}
+
+func (o *recordingObserver) OnVerifyFail(ctx context.Context, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sawVerifyFail = true
+	o.verifyFailID, _ = ContextDeliveryID(ctx)
+}
+
+func (o *recordingObserver) OnDecodeFail(context.Context, error, []byte) {}
+
+func (o *recordingObserver) OnHandlerResult(ctx context.Context, pattern string, object decode.Object, dur time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.resultID, _ = ContextDeliveryID(ctx)
+}
+
+func TestServerPropagatesDeliveryIDToObserver(t *testing.T) {
+	ws := NewServer("secret", "token", "verify")
+	obs := &recordingObserver{}
+	ws.Observer = obs
+	ws.HandleObjectFunc("/webhook", "page", func(decode.RequestBody) error { return nil })
+	handler := ws.getObjectHandlerFunc("/webhook")
+
+	// A bad signature should still carry the delivery ID through to OnVerifyFail.
+	payload := []byte(`{"object":"page"}`)
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	req.Header.Set("X-Hub-Signature-256", "sha256=deadbeef")
+	req.Header.Set("X-Hub-Delivery", "delivery-bad-sig")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if !obs.sawVerifyFail || obs.verifyFailID != "delivery-bad-sig" {
+		t.Fatalf("expected OnVerifyFail to see delivery ID %q, got sawVerifyFail=%v id=%q", "delivery-bad-sig", obs.sawVerifyFail, obs.verifyFailID)
+	}
+
+	rec = postWebhook(t, handler, "delivery-ok", payload)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if obs.requestID != "delivery-ok" || obs.resultID != "delivery-ok" {
+		t.Fatalf("expected OnRequest/OnHandlerResult to see delivery ID %q, got request=%q result=%q", "delivery-ok", obs.requestID, obs.resultID)
+	}
+}
@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// SignatureError reports why a webhook signature failed verification.
+// Middleware can use errors.Is against ErrMissingSignature and
+// ErrInvalidSignature to tell the two cases apart.
+type SignatureError struct {
+	Reason string
+}
+
+func (e *SignatureError) Error() string {
+	return "workplace: signature " + e.Reason
+}
+
+// ErrMissingSignature is returned when a request carries neither
+// X-Hub-Signature-256 nor X-Hub-Signature.
+var ErrMissingSignature = &SignatureError{Reason: "missing"}
+
+// ErrInvalidSignature is returned when none of the configured secrets
+// produce a matching HMAC for the supplied signature.
+var ErrInvalidSignature = &SignatureError{Reason: "invalid"}
+
+// verifySignature checks the request payload against X-Hub-Signature-256
+// (SHA-256, preferred) or, if absent, X-Hub-Signature (SHA-1). It tries
+// each of secrets in turn and compares with hmac.Equal to avoid leaking
+// timing information.
+func verifySignature(header http.Header, secrets []string, payload []byte) error {
+	newHash, signatureHash, err := signatureHashFunc(header)
+	if err != nil {
+		return err
+	}
+
+	expected, err := hex.DecodeString(signatureHash)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	for _, secret := range secrets {
+		mac := hmac.New(newHash, []byte(secret))
+		mac.Write(payload)
+		if hmac.Equal(expected, mac.Sum(nil)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// signatureHashFunc picks the signature header to trust, preferring the
+// SHA-256 one, and returns the matching hash constructor along with the
+// hex-encoded digest carried after the "sha1="/"sha256=" prefix.
+func signatureHashFunc(header http.Header) (func() hash.Hash, string, error) {
+	if sig := header.Get("X-Hub-Signature-256"); sig != "" {
+		digest, err := signatureDigest(sig, "sha256=")
+		if err != nil {
+			return nil, "", err
+		}
+		return sha256.New, digest, nil
+	}
+	if sig := header.Get("X-Hub-Signature"); sig != "" {
+		digest, err := signatureDigest(sig, "sha1=")
+		if err != nil {
+			return nil, "", err
+		}
+		return sha1.New, digest, nil
+	}
+	return nil, "", ErrMissingSignature
+}
+
+func signatureDigest(sig, prefix string) (string, error) {
+	if !strings.HasPrefix(sig, prefix) {
+		return "", ErrInvalidSignature
+	}
+	digest := strings.TrimPrefix(sig, prefix)
+	if digest == "" {
+		return "", ErrInvalidSignature
+	}
+	return digest, nil
+}
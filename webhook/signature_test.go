@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+)
+
+func sha256Signature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func sha1Signature(secret string, payload []byte) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(payload)
+	return "sha1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignaturePrefersSHA256(t *testing.T) {
+	payload := []byte(`{"object":"page"}`)
+	secret := "top-secret"
+
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", sha256Signature(secret, payload))
+	header.Set("X-Hub-Signature", "sha1=deadbeef") // deliberately wrong, must be ignored
+
+	if err := verifySignature(header, []string{secret}, payload); err != nil {
+		t.Fatalf("expected SHA-256 signature to verify, got %v", err)
+	}
+}
+
+func TestVerifySignatureFallsBackToSHA1(t *testing.T) {
+	payload := []byte(`{"object":"page"}`)
+	secret := "top-secret"
+
+	header := http.Header{}
+	header.Set("X-Hub-Signature", sha1Signature(secret, payload))
+
+	if err := verifySignature(header, []string{secret}, payload); err != nil {
+		t.Fatalf("expected SHA-1 signature to verify, got %v", err)
+	}
+}
+
+func TestVerifySignatureRotation(t *testing.T) {
+	payload := []byte(`{"object":"page"}`)
+	oldSecret, newSecret := "old-secret", "new-secret"
+
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", sha256Signature(newSecret, payload))
+
+	if err := verifySignature(header, []string{oldSecret, newSecret}, payload); err != nil {
+		t.Fatalf("expected rotation to accept the new secret, got %v", err)
+	}
+}
+
+func TestVerifySignatureMissing(t *testing.T) {
+	err := verifySignature(http.Header{}, []string{"secret"}, []byte("{}"))
+	if err != ErrMissingSignature {
+		t.Fatalf("expected ErrMissingSignature, got %v", err)
+	}
+}
+
+func TestVerifySignatureInvalid(t *testing.T) {
+	payload := []byte(`{"object":"page"}`)
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", sha256Signature("wrong-secret", payload))
+
+	err := verifySignature(header, []string{"right-secret"}, payload)
+	if err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+}
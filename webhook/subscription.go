@@ -0,0 +1,197 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sunmyinf/go-workplace/decode"
+)
+
+// defaultGraphBaseURL is the root of the Facebook Graph API that backs
+// Workplace subscriptions.
+const defaultGraphBaseURL = "https://graph.facebook.com"
+
+// errMissingAppID is returned by SubscriptionClient methods when AppID
+// is unset, instead of letting them build a malformed Graph API URL and
+// fail with an opaque remote error.
+var errMissingAppID = errors.New("workplace: SubscriptionClient.AppID is required")
+
+// Subscription describes one object subscription as returned by the
+// Graph API's GET /{app-id}/subscriptions.
+type Subscription struct {
+	Object      string              `json:"object"`
+	CallbackURL string              `json:"callback_url"`
+	Active      bool                `json:"active"`
+	Fields      []SubscriptionField `json:"fields"`
+}
+
+// SubscriptionField is one field an app subscribed to on an object.
+type SubscriptionField struct {
+	Name string `json:"name"`
+}
+
+// SubscriptionClient manages the app's Workplace webhook subscriptions
+// through the Graph API's /{app-id}/subscriptions endpoints.
+type SubscriptionClient struct {
+	AppID       string
+	AccessToken string
+	// BaseURL overrides defaultGraphBaseURL, e.g. to point at an
+	// httptest.Server in tests.
+	BaseURL string
+	// HTTPClient is used to make requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Subscriptions returns a SubscriptionClient for ws's app, reusing its
+// AppID and AccessToken.
+func (ws *Server) Subscriptions() *SubscriptionClient {
+	return &SubscriptionClient{
+		AppID:       ws.AppID,
+		AccessToken: ws.AccessToken,
+	}
+}
+
+func (c *SubscriptionClient) baseURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return defaultGraphBaseURL
+}
+
+func (c *SubscriptionClient) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *SubscriptionClient) subscriptionsURL() string {
+	return fmt.Sprintf("%s/%s/subscriptions", c.baseURL(), c.AppID)
+}
+
+func (c *SubscriptionClient) do(ctx context.Context, method, rawURL string, form url.Values) (*http.Response, error) {
+	var req *http.Request
+	var err error
+	switch method {
+	case http.MethodGet, http.MethodDelete:
+		if len(form) > 0 {
+			rawURL += "?" + form.Encode()
+		}
+		req, err = http.NewRequestWithContext(ctx, method, rawURL, nil)
+	default:
+		req, err = http.NewRequestWithContext(ctx, method, rawURL, strings.NewReader(form.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.httpClient().Do(req)
+}
+
+func checkGraphResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	var graphErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&graphErr)
+	if graphErr.Error.Message != "" {
+		return fmt.Errorf("workplace: graph api error: %s", graphErr.Error.Message)
+	}
+	return fmt.Errorf("workplace: graph api returned status %d", resp.StatusCode)
+}
+
+// List returns every object the app is currently subscribed to.
+func (c *SubscriptionClient) List(ctx context.Context) ([]Subscription, error) {
+	if c.AppID == "" {
+		return nil, errMissingAppID
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, c.subscriptionsURL(), url.Values{
+		"access_token": {c.AccessToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkGraphResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		Data []Subscription `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Data, nil
+}
+
+// Subscribe subscribes object to callbackURL for the given fields,
+// creating the subscription if it doesn't exist yet or updating it if
+// it does.
+func (c *SubscriptionClient) Subscribe(ctx context.Context, object decode.Object, callbackURL string, fields []string) error {
+	if c.AppID == "" {
+		return errMissingAppID
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, c.subscriptionsURL(), url.Values{
+		"access_token": {c.AccessToken},
+		"object":       {string(object)},
+		"callback_url": {callbackURL},
+		"fields":       {strings.Join(fields, ",")},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkGraphResponse(resp)
+}
+
+// Unsubscribe removes the app's subscription to object.
+func (c *SubscriptionClient) Unsubscribe(ctx context.Context, object decode.Object) error {
+	if c.AppID == "" {
+		return errMissingAppID
+	}
+
+	resp, err := c.do(ctx, http.MethodDelete, c.subscriptionsURL(), url.Values{
+		"access_token": {c.AccessToken},
+		"object":       {string(object)},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkGraphResponse(resp)
+}
+
+// Reactivate re-enables object's subscription after Meta deactivated it,
+// e.g. after repeated delivery failures, without changing its callback
+// URL or fields.
+func (c *SubscriptionClient) Reactivate(ctx context.Context, object decode.Object) error {
+	if c.AppID == "" {
+		return errMissingAppID
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, c.subscriptionsURL(), url.Values{
+		"access_token": {c.AccessToken},
+		"object":       {string(object)},
+		"active":       {"true"},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkGraphResponse(resp)
+}
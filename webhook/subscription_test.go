@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sunmyinf/go-workplace/decode"
+)
+
+func TestSubscriptionClientRequiresAppID(t *testing.T) {
+	c := &SubscriptionClient{AccessToken: "token"}
+
+	if _, err := c.List(context.Background()); err != errMissingAppID {
+		t.Fatalf("expected errMissingAppID, got %v", err)
+	}
+	if err := c.Subscribe(context.Background(), "page", "https://example.com", nil); err != errMissingAppID {
+		t.Fatalf("expected errMissingAppID, got %v", err)
+	}
+	if err := c.Unsubscribe(context.Background(), "page"); err != errMissingAppID {
+		t.Fatalf("expected errMissingAppID, got %v", err)
+	}
+	if err := c.Reactivate(context.Background(), "page"); err != errMissingAppID {
+		t.Fatalf("expected errMissingAppID, got %v", err)
+	}
+}
+
+func TestSubscriptionClientList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/123/subscriptions" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		w.Write([]byte(`{"data":[{"object":"page","callback_url":"https://example.com","active":true,"fields":[{"name":"feed"}]}]}`))
+	}))
+	defer server.Close()
+
+	c := &SubscriptionClient{AppID: "123", AccessToken: "token", BaseURL: server.URL}
+	subs, err := c.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(subs) != 1 || subs[0].Object != "page" || !subs[0].Active {
+		t.Fatalf("unexpected subscriptions: %+v", subs)
+	}
+}
+
+func TestSubscriptionClientSubscribe(t *testing.T) {
+	var gotForm url.Values
+	var gotMethod string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		r.ParseForm()
+		gotForm = r.PostForm
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := &SubscriptionClient{AppID: "123", AccessToken: "token", BaseURL: server.URL}
+	err := c.Subscribe(context.Background(), decode.Object("page"), "https://example.com/hook", []string{"feed", "mention"})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if gotMethod != http.MethodPost {
+		t.Fatalf("expected POST, got %s", gotMethod)
+	}
+	if gotForm.Get("object") != "page" || gotForm.Get("fields") != "feed,mention" {
+		t.Fatalf("unexpected form: %+v", gotForm)
+	}
+}
+
+func TestSubscriptionClientGraphErrorIsSurfaced(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"Invalid OAuth access token."}}`))
+	}))
+	defer server.Close()
+
+	c := &SubscriptionClient{AppID: "123", AccessToken: "bad-token", BaseURL: server.URL}
+	err := c.Unsubscribe(context.Background(), decode.Object("page"))
+	if err == nil {
+		t.Fatal("expected an error from a non-2xx Graph API response")
+	}
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/sunmyinf/go-workplace/decode"
+)
+
+type contextKey int
+
+const requestBodyContextKey contextKey = iota
+
+// Verifier verifies the X-Hub-Signature(-256) of Workplace webhook
+// requests and makes the decoded body available to downstream handlers,
+// without requiring callers to adopt Server's own mux and object
+// dispatch. It can wrap any http.Handler, so it plugs straight into
+// chi, gorilla/mux, echo, or the standard library.
+type Verifier struct {
+	Secrets []string
+	// OnVerifyFail, if set, is called when signature verification fails.
+	OnVerifyFail func(ctx context.Context, err error)
+	// OnDecodeFail, if set, is called when the payload can't be
+	// unmarshalled into decode.RequestBody.
+	OnDecodeFail func(ctx context.Context, err error, rawBody []byte)
+}
+
+// NewVerifier returns a Verifier that accepts signatures produced by any
+// one of secrets.
+func NewVerifier(secrets ...string) *Verifier {
+	return &Verifier{Secrets: secrets}
+}
+
+// Wrap returns an http.Handler that verifies the request signature before
+// calling next. On failure it writes the response itself and never calls
+// next.
+func (v *Verifier) Wrap(next http.Handler) http.Handler {
+	return v.WrapFunc(next.ServeHTTP)
+}
+
+// WrapFunc is Wrap for an http.HandlerFunc.
+func (v *Verifier) WrapFunc(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.Body.Close()
+
+		if err := verifySignature(r.Header, v.Secrets, buf); err != nil {
+			if v.OnVerifyFail != nil {
+				v.OnVerifyFail(r.Context(), err)
+			}
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		reqBody := decode.RequestBody{}
+		if err := json.Unmarshal(buf, &reqBody); err != nil {
+			if v.OnDecodeFail != nil {
+				v.OnDecodeFail(r.Context(), err, buf)
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Downstream handlers may want to read the raw body again (e.g.
+		// to unmarshal into a more specific type), so put a fresh reader
+		// back before calling next.
+		r.Body = io.NopCloser(bytes.NewReader(buf))
+		ctx := context.WithValue(r.Context(), requestBodyContextKey, reqBody)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// ContextRequestBody returns the decode.RequestBody a Verifier stored on
+// r's context, and whether one was present.
+func ContextRequestBody(r *http.Request) (decode.RequestBody, bool) {
+	reqBody, ok := r.Context().Value(requestBodyContextKey).(decode.RequestBody)
+	return reqBody, ok
+}
@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVerifierWrapFuncRejectsBadSignature(t *testing.T) {
+	v := NewVerifier("secret")
+	called := false
+	handler := v.WrapFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	req.Header.Set("X-Hub-Signature-256", "sha256=deadbeef")
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if called {
+		t.Fatal("next should not be called when signature verification fails")
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+}
+
+func TestVerifierWrapFuncPassesDecodedBodyAndRewindsReader(t *testing.T) {
+	payload := []byte(`{"object":"page"}`)
+	v := NewVerifier("secret")
+
+	var gotObject string
+	var gotRaw []byte
+	handler := v.WrapFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqBody, ok := ContextRequestBody(r)
+		if !ok {
+			t.Fatal("expected ContextRequestBody to find a decoded body")
+		}
+		gotObject = string(reqBody.Object)
+
+		var err error
+		gotRaw, err = io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("re-reading r.Body failed: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	req.Body = io.NopCloser(bytes.NewReader(payload))
+	req.Header.Set("X-Hub-Signature-256", sha256Signature("secret", payload))
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if gotObject != "page" {
+		t.Fatalf("expected decoded object %q, got %q", "page", gotObject)
+	}
+	if string(gotRaw) != string(payload) {
+		t.Fatalf("expected r.Body to be re-readable with the original payload, got %q", gotRaw)
+	}
+}